@@ -0,0 +1,60 @@
+package ctxgrpc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/peterbourgon/ctxdata"
+)
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor that Sets
+// grpc.service, grpc.method, grpc.duration, grpc.code and, on error,
+// grpc.err around the outgoing call, into whatever Data is already present in
+// ctx. It doesn't Set grpc.peer or grpc.deadline. Unlike the server-side
+// interceptors, it doesn't call ctxdata.New: client calls are typically made
+// from within an existing request's Data scope, so this enriches that Data
+// rather than replacing it.
+func UnaryClientInterceptor(opts Opts) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		d := ctxdata.From(ctx)
+		begin := time.Now()
+
+		service, m := splitMethod(method)
+		d.Set("grpc.service", service)
+		d.Set("grpc.method", m)
+
+		err := invoker(ctx, method, req, reply, cc, callOpts...)
+
+		setResultKeys(d, begin, err)
+		if opts.Logf != nil {
+			opts.Logf(ctx, d)
+		}
+
+		return err
+	}
+}
+
+// StreamClientInterceptor returns a grpc.StreamClientInterceptor that Sets
+// the same standard gRPC keys as UnaryClientInterceptor around stream
+// creation, into whatever Data is already present in ctx.
+func StreamClientInterceptor(opts Opts) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		d := ctxdata.From(ctx)
+		begin := time.Now()
+
+		service, m := splitMethod(method)
+		d.Set("grpc.service", service)
+		d.Set("grpc.method", m)
+
+		cs, err := streamer(ctx, desc, cc, method, callOpts...)
+
+		setResultKeys(d, begin, err)
+		if opts.Logf != nil {
+			opts.Logf(ctx, d)
+		}
+
+		return cs, err
+	}
+}
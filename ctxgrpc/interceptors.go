@@ -0,0 +1,148 @@
+// Package ctxgrpc provides gRPC unary and stream interceptors, for both
+// servers and clients, that wire an RPC's lifecycle into a ctxdata.Data,
+// mirroring the ctxhttp package's HTTP middleware.
+//
+// This package depends on google.golang.org/grpc, pinned in go.mod to a
+// release that supports the module's go 1.21 floor; later grpc-go releases
+// require newer Go versions.
+package ctxgrpc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/peterbourgon/ctxdata"
+)
+
+// Opts control the behavior of the interceptors in this package.
+type Opts struct {
+	// Logf, if set, is invoked once per RPC, after the handler or invoker
+	// has returned and the standard keys have been Set, so callers can
+	// emit a single structured log line per RPC.
+	Logf func(context.Context, *ctxdata.Data)
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that calls
+// ctxdata.New on the incoming context, and Sets a standard set of
+// gRPC-related keys into the resulting Data once the handler returns,
+// whether it returns normally, with an error, or with a panic.
+func UnaryServerInterceptor(opts Opts) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		ctx, d := ctxdata.New(ctx)
+		begin := time.Now()
+
+		setRequestKeys(ctx, d, info.FullMethod)
+
+		defer func() {
+			p := recover()
+			finishResult(ctx, d, begin, info.FullMethod, p, &err, opts)
+		}()
+
+		resp, err = handler(ctx, req)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that calls
+// ctxdata.New on the stream's context, and Sets the same standard keys as
+// UnaryServerInterceptor once the handler returns, whether it returns
+// normally, with an error, or with a panic. The injected context is wrapped
+// around the stream, so it's visible to the handler via stream.Context().
+func StreamServerInterceptor(opts Opts) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		ctx, d := ctxdata.New(ss.Context())
+		begin := time.Now()
+
+		setRequestKeys(ctx, d, info.FullMethod)
+
+		defer func() {
+			p := recover()
+			finishResult(ctx, d, begin, info.FullMethod, p, &err, opts)
+		}()
+
+		err = handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		return err
+	}
+}
+
+// serverStream overrides Context so the injected ctxdata.Data is visible to
+// handlers calling stream.Context().
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context implements grpc.ServerStream.
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func setRequestKeys(ctx context.Context, d *ctxdata.Data, fullMethod string) {
+	service, method := splitMethod(fullMethod)
+	d.Set("grpc.service", service)
+	d.Set("grpc.method", method)
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		d.Set("grpc.peer", p.Addr.String())
+	}
+	if dl, ok := ctx.Deadline(); ok {
+		d.Set("grpc.deadline", dl)
+	}
+}
+
+// setResultKeys Sets the standard result keys for an RPC that completed
+// without the interceptor itself needing to recover a panic, i.e. the
+// client-side interceptors in client.go.
+func setResultKeys(d *ctxdata.Data, begin time.Time, err error) {
+	d.Set("grpc.duration", time.Since(begin))
+	d.Set("grpc.code", status.Code(err).String())
+	if err != nil {
+		d.Set("grpc.err", err.Error())
+	}
+}
+
+// finishResult converts a recovered panic p (nil if the handler returned
+// normally) into an Internal error returned to the client, Setting
+// grpc.panic/grpc.panic.stack, then Sets the standard result keys and calls
+// Opts.Logf. The caller must pass the result of a recover() call made
+// directly in its own deferred function, since recover only stops a panic
+// when called directly by the deferred function, not by a function it calls.
+func finishResult(ctx context.Context, d *ctxdata.Data, begin time.Time, fullMethod string, p interface{}, err *error, opts Opts) {
+	if p != nil {
+		stack := debug.Stack()
+		d.Set("grpc.panic", fmt.Sprintf("%v", p))
+		d.Set("grpc.panic.stack", string(stack))
+		*err = status.Errorf(codes.Internal, "panic: %v", p)
+		if opts.Logf == nil {
+			log.Printf("ctxgrpc: panic serving %s: %v\n%s", fullMethod, p, stack)
+		}
+	}
+
+	d.Set("grpc.duration", time.Since(begin))
+	d.Set("grpc.code", status.Code(*err).String())
+	if *err != nil {
+		d.Set("grpc.err", (*err).Error())
+	}
+
+	if opts.Logf != nil {
+		opts.Logf(ctx, d)
+	}
+}
+
+// splitMethod splits a full gRPC method name, e.g. "/pkg.Service/Method",
+// into its service and method parts.
+func splitMethod(fullMethod string) (service, method string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return fullMethod, ""
+}
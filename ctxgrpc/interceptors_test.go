@@ -0,0 +1,146 @@
+package ctxgrpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/peterbourgon/ctxdata"
+	"github.com/peterbourgon/ctxdata/ctxgrpc"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	t.Parallel()
+
+	var logged *ctxdata.Data
+
+	interceptor := ctxgrpc.UnaryServerInterceptor(ctxgrpc.Opts{
+		Logf: func(_ context.Context, d *ctxdata.Data) {
+			logged = d
+		},
+	})
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/pkg.Greeter/SayHello"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "reply", nil
+	}
+
+	resp, err := interceptor(context.Background(), "request", info, handler)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want, have := "reply", resp; want != have {
+		t.Errorf("resp: want %v, have %v", want, have)
+	}
+
+	if logged == nil {
+		t.Fatal("Logf was not called")
+	}
+
+	m := logged.GetAllMap()
+	if want, have := "pkg.Greeter", m["grpc.service"]; want != have {
+		t.Errorf("grpc.service: want %v, have %v", want, have)
+	}
+	if want, have := "SayHello", m["grpc.method"]; want != have {
+		t.Errorf("grpc.method: want %v, have %v", want, have)
+	}
+	if want, have := codes.OK.String(), m["grpc.code"]; want != have {
+		t.Errorf("grpc.code: want %v, have %v", want, have)
+	}
+}
+
+func TestUnaryServerInterceptorError(t *testing.T) {
+	t.Parallel()
+
+	var logged *ctxdata.Data
+
+	interceptor := ctxgrpc.UnaryServerInterceptor(ctxgrpc.Opts{
+		Logf: func(_ context.Context, d *ctxdata.Data) {
+			logged = d
+		},
+	})
+
+	wantErr := status.Error(codes.NotFound, "not found")
+	info := &grpc.UnaryServerInfo{FullMethod: "/pkg.Greeter/SayHello"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, wantErr
+	}
+
+	_, err := interceptor(context.Background(), "request", info, handler)
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("want error %v, have %v", wantErr, err)
+	}
+
+	m := logged.GetAllMap()
+	if want, have := codes.NotFound.String(), m["grpc.code"]; want != have {
+		t.Errorf("grpc.code: want %v, have %v", want, have)
+	}
+	if _, ok := m["grpc.err"]; !ok {
+		t.Error("grpc.err: want present, have absent")
+	}
+}
+
+func TestUnaryServerInterceptorPanic(t *testing.T) {
+	t.Parallel()
+
+	var logged *ctxdata.Data
+
+	interceptor := ctxgrpc.UnaryServerInterceptor(ctxgrpc.Opts{
+		Logf: func(_ context.Context, d *ctxdata.Data) {
+			logged = d
+		},
+	})
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/pkg.Greeter/SayHello"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("boom")
+	}
+
+	_, err := interceptor(context.Background(), "request", info, handler)
+	if want, have := codes.Internal, status.Code(err); want != have {
+		t.Fatalf("code: want %v, have %v", want, have)
+	}
+
+	if logged == nil {
+		t.Fatal("Logf was not called")
+	}
+
+	m := logged.GetAllMap()
+	if want, have := "boom", m["grpc.panic"]; want != have {
+		t.Errorf("grpc.panic: want %v, have %v", want, have)
+	}
+	if _, ok := m["grpc.panic.stack"]; !ok {
+		t.Error("grpc.panic.stack: want present, have absent")
+	}
+	if want, have := codes.Internal.String(), m["grpc.code"]; want != have {
+		t.Errorf("grpc.code: want %v, have %v", want, have)
+	}
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	t.Parallel()
+
+	ctx, d := ctxdata.New(context.Background())
+
+	interceptor := ctxgrpc.UnaryClientInterceptor(ctxgrpc.Opts{})
+
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return nil
+	}
+
+	if err := interceptor(ctx, "/pkg.Greeter/SayHello", "req", "reply", nil, invoker); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	m := d.GetAllMap()
+	if want, have := "pkg.Greeter", m["grpc.service"]; want != have {
+		t.Errorf("grpc.service: want %v, have %v", want, have)
+	}
+	if want, have := "SayHello", m["grpc.method"]; want != have {
+		t.Errorf("grpc.method: want %v, have %v", want, have)
+	}
+}
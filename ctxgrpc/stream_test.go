@@ -0,0 +1,135 @@
+package ctxgrpc_test
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/peterbourgon/ctxdata"
+	"github.com/peterbourgon/ctxdata/ctxgrpc"
+)
+
+// fakeServerStream is a minimal grpc.ServerStream for tests.
+type fakeServerStream struct {
+	ctx context.Context
+}
+
+func (s *fakeServerStream) SetHeader(metadata.MD) error  { return nil }
+func (s *fakeServerStream) SendHeader(metadata.MD) error { return nil }
+func (s *fakeServerStream) SetTrailer(metadata.MD)       {}
+func (s *fakeServerStream) Context() context.Context     { return s.ctx }
+func (s *fakeServerStream) SendMsg(m interface{}) error  { return nil }
+func (s *fakeServerStream) RecvMsg(m interface{}) error  { return nil }
+
+func TestStreamServerInterceptor(t *testing.T) {
+	t.Parallel()
+
+	var logged *ctxdata.Data
+	var sawCtx context.Context
+
+	interceptor := ctxgrpc.StreamServerInterceptor(ctxgrpc.Opts{
+		Logf: func(_ context.Context, d *ctxdata.Data) {
+			logged = d
+		},
+	})
+
+	info := &grpc.StreamServerInfo{FullMethod: "/pkg.Greeter/SayHelloStream"}
+	ss := &fakeServerStream{ctx: context.Background()}
+
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		sawCtx = stream.Context()
+		ctxdata.From(sawCtx).Set("inner", "a")
+		return nil
+	}
+
+	if err := interceptor(nil, ss, info, handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if sawCtx == ss.ctx {
+		t.Fatal("stream.Context() inside handler was not replaced with the injected context")
+	}
+	if d := ctxdata.From(sawCtx); d == nil {
+		t.Fatal("stream.Context() inside handler has no ctxdata.Data")
+	}
+
+	if logged == nil {
+		t.Fatal("Logf was not called")
+	}
+
+	m := logged.GetAllMap()
+	if want, have := "pkg.Greeter", m["grpc.service"]; want != have {
+		t.Errorf("grpc.service: want %v, have %v", want, have)
+	}
+	if want, have := "SayHelloStream", m["grpc.method"]; want != have {
+		t.Errorf("grpc.method: want %v, have %v", want, have)
+	}
+	if want, have := "a", m["inner"]; want != have {
+		t.Errorf("inner: want %v, have %v", want, have)
+	}
+	if want, have := codes.OK.String(), m["grpc.code"]; want != have {
+		t.Errorf("grpc.code: want %v, have %v", want, have)
+	}
+}
+
+func TestStreamServerInterceptorPanic(t *testing.T) {
+	t.Parallel()
+
+	var logged *ctxdata.Data
+
+	interceptor := ctxgrpc.StreamServerInterceptor(ctxgrpc.Opts{
+		Logf: func(_ context.Context, d *ctxdata.Data) {
+			logged = d
+		},
+	})
+
+	info := &grpc.StreamServerInfo{FullMethod: "/pkg.Greeter/SayHelloStream"}
+	ss := &fakeServerStream{ctx: context.Background()}
+
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		panic("stream boom")
+	}
+
+	err := interceptor(nil, ss, info, handler)
+	if want, have := codes.Internal, status.Code(err); want != have {
+		t.Fatalf("code: want %v, have %v", want, have)
+	}
+
+	m := logged.GetAllMap()
+	if want, have := "stream boom", m["grpc.panic"]; want != have {
+		t.Errorf("grpc.panic: want %v, have %v", want, have)
+	}
+}
+
+func TestStreamClientInterceptor(t *testing.T) {
+	t.Parallel()
+
+	ctx, d := ctxdata.New(context.Background())
+
+	interceptor := ctxgrpc.StreamClientInterceptor(ctxgrpc.Opts{})
+
+	var fakeStream grpc.ClientStream
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return fakeStream, nil
+	}
+
+	cs, err := interceptor(ctx, &grpc.StreamDesc{}, nil, "/pkg.Greeter/SayHelloStream", streamer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want, have := fakeStream, cs; want != have {
+		t.Errorf("stream: want %v, have %v", want, have)
+	}
+
+	m := d.GetAllMap()
+	if want, have := "pkg.Greeter", m["grpc.service"]; want != have {
+		t.Errorf("grpc.service: want %v, have %v", want, have)
+	}
+	if want, have := "SayHelloStream", m["grpc.method"]; want != have {
+		t.Errorf("grpc.method: want %v, have %v", want, have)
+	}
+}
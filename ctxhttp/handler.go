@@ -0,0 +1,174 @@
+// Package ctxhttp provides an HTTP middleware that wires a request's
+// lifecycle into a ctxdata.Data, modeled on Tailscale's tsweb.StdHandler.
+package ctxhttp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/peterbourgon/ctxdata"
+)
+
+// ReturnHandler is like http.Handler, but its ServeHTTPReturn method returns
+// an error instead of writing one directly. StdHandler uses the returned
+// error to populate Data and, absent an Opts.OnError hook, to write a default
+// error response.
+type ReturnHandler interface {
+	ServeHTTPReturn(w http.ResponseWriter, r *http.Request) error
+}
+
+// ReturnHandlerFunc adapts a function to a ReturnHandler.
+type ReturnHandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// ServeHTTPReturn implements ReturnHandler.
+func (f ReturnHandlerFunc) ServeHTTPReturn(w http.ResponseWriter, r *http.Request) error {
+	return f(w, r)
+}
+
+// Opts control the behavior of StdHandler.
+type Opts struct {
+	// Route, if set, is called to produce the route template for the
+	// request, e.g. "/users/:id" rather than "/users/123". It's Set into
+	// Data as http.route.
+	Route func(*http.Request) string
+
+	// Logf, if set, is invoked once per request, after the inner handler
+	// has returned and all of the standard keys have been Set, so callers
+	// can emit a single structured log line per request.
+	Logf func(context.Context, *ctxdata.Data)
+
+	// OnError, if set, is invoked when the inner handler returns a non-nil
+	// error. It should write a response to w and return true to indicate
+	// that it did so. If it returns false, or is nil, StdHandler writes a
+	// default error response.
+	OnError func(w http.ResponseWriter, r *http.Request, err error) bool
+}
+
+// StdHandler adapts h to an http.Handler. It calls ctxdata.New on the
+// incoming request, and automatically Sets a standard set of HTTP-related
+// keys into the resulting Data once the request completes, whether it
+// completes normally, with an error, or with a panic.
+func StdHandler(h ReturnHandler, opts Opts) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, d := ctxdata.New(r.Context())
+		r = r.WithContext(ctx)
+
+		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+		begin := time.Now()
+
+		defer func() {
+			d.Set("http.method", r.Method)
+			d.Set("http.path", r.URL.Path)
+			if opts.Route != nil {
+				d.Set("http.route", opts.Route(r))
+			}
+			d.Set("http.remote_addr", r.RemoteAddr)
+			if id := r.Header.Get("X-Request-Id"); id != "" {
+				d.Set("http.request_id", id)
+			}
+
+			if p := recover(); p != nil {
+				stack := debug.Stack()
+				d.Set("http.panic", fmt.Sprintf("%v", p))
+				d.Set("http.panic.stack", string(stack))
+				if !rw.wrote {
+					rw.WriteHeader(http.StatusInternalServerError)
+				}
+				if opts.Logf == nil {
+					log.Printf("ctxhttp: panic serving %s %s: %v\n%s", r.Method, r.URL.Path, p, stack)
+				}
+			}
+
+			d.Set("http.status", rw.status)
+			d.Set("http.bytes_written", rw.written)
+			d.Set("http.duration", time.Since(begin))
+
+			if opts.Logf != nil {
+				opts.Logf(r.Context(), d)
+			}
+		}()
+
+		if err := h.ServeHTTPReturn(rw, r); err != nil {
+			if ve, ok := ctxdata.As(err); ok {
+				d.Set("http.err.internal", err.Error())
+				d.Set("http.err.public", ve.Public())
+				if opts.OnError == nil || !opts.OnError(rw, r, err) {
+					http.Error(rw, ve.Public(), ve.Status())
+				}
+			} else {
+				d.Set("http.err", err.Error())
+				if opts.OnError == nil || !opts.OnError(rw, r, err) {
+					http.Error(rw, err.Error(), http.StatusInternalServerError)
+				}
+			}
+		}
+	})
+}
+
+// responseWriter wraps an http.ResponseWriter to record the status code and
+// number of bytes written, while passing through the optional http.Hijacker,
+// http.Flusher, and http.CloseNotifier interfaces that the underlying
+// ResponseWriter may implement.
+type responseWriter struct {
+	http.ResponseWriter
+	status  int
+	written int64
+	wrote   bool
+}
+
+func (rw *responseWriter) WriteHeader(code int) {
+	if !rw.wrote {
+		rw.status = code
+		rw.wrote = true
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *responseWriter) Write(p []byte) (int, error) {
+	if !rw.wrote {
+		rw.WriteHeader(http.StatusOK)
+	}
+	n, err := rw.ResponseWriter.Write(p)
+	rw.written += int64(n)
+	return n, err
+}
+
+// Hijack implements http.Hijacker, passing through to the underlying
+// ResponseWriter if it supports it. A successful hijack marks the response as
+// already written, so a panic afterwards doesn't try to WriteHeader on the
+// now-hijacked connection.
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("underlying ResponseWriter does not implement http.Hijacker")
+	}
+	conn, buf, err := hj.Hijack()
+	if err == nil {
+		rw.wrote = true
+	}
+	return conn, buf, err
+}
+
+// Flush implements http.Flusher, passing through to the underlying
+// ResponseWriter if it supports it.
+func (rw *responseWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// CloseNotify implements the deprecated http.CloseNotifier, passing through
+// to the underlying ResponseWriter if it supports it. It's retained only for
+// handlers that still depend on it.
+func (rw *responseWriter) CloseNotify() <-chan bool {
+	if cn, ok := rw.ResponseWriter.(http.CloseNotifier); ok { //nolint:staticcheck // passthrough
+		return cn.CloseNotify()
+	}
+	return make(chan bool)
+}
@@ -0,0 +1,192 @@
+package ctxhttp_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/peterbourgon/ctxdata"
+	"github.com/peterbourgon/ctxdata/ctxhttp"
+)
+
+func TestStdHandlerBasics(t *testing.T) {
+	t.Parallel()
+
+	var logged *ctxdata.Data
+
+	h := ctxhttp.StdHandler(ctxhttp.ReturnHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+		return nil
+	}), ctxhttp.Opts{
+		Logf: func(_ context.Context, d *ctxdata.Data) {
+			logged = d
+		},
+	})
+
+	r := httptest.NewRequest("GET", "/path", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if want, have := http.StatusCreated, w.Code; want != have {
+		t.Errorf("status: want %d, have %d", want, have)
+	}
+
+	if logged == nil {
+		t.Fatal("Logf was not called")
+	}
+
+	m := logged.GetAllMap()
+	if want, have := "GET", m["http.method"]; want != have {
+		t.Errorf("http.method: want %v, have %v", want, have)
+	}
+	if want, have := "/path", m["http.path"]; want != have {
+		t.Errorf("http.path: want %v, have %v", want, have)
+	}
+	if want, have := http.StatusCreated, m["http.status"]; want != have {
+		t.Errorf("http.status: want %v, have %v", want, have)
+	}
+}
+
+func TestStdHandlerError(t *testing.T) {
+	t.Parallel()
+
+	wantErr := errors.New("boom")
+
+	var handled error
+	h := ctxhttp.StdHandler(ctxhttp.ReturnHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return wantErr
+	}), ctxhttp.Opts{
+		OnError: func(w http.ResponseWriter, r *http.Request, err error) bool {
+			handled = err
+			w.WriteHeader(http.StatusTeapot)
+			return true
+		},
+	})
+
+	r := httptest.NewRequest("GET", "/path", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if want, have := wantErr, handled; want != have {
+		t.Errorf("OnError err: want %v, have %v", want, have)
+	}
+	if want, have := http.StatusTeapot, w.Code; want != have {
+		t.Errorf("status: want %d, have %d", want, have)
+	}
+}
+
+func TestStdHandlerVisibleError(t *testing.T) {
+	t.Parallel()
+
+	var logged *ctxdata.Data
+
+	h := ctxhttp.StdHandler(ctxhttp.ReturnHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return ctxdata.Wrap(errors.New("db connection reset"), http.StatusServiceUnavailable, "please try again later")
+	}), ctxhttp.Opts{
+		Logf: func(_ context.Context, d *ctxdata.Data) {
+			logged = d
+		},
+	})
+
+	r := httptest.NewRequest("GET", "/path", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if want, have := http.StatusServiceUnavailable, w.Code; want != have {
+		t.Errorf("status: want %d, have %d", want, have)
+	}
+	if want, have := "please try again later\n", w.Body.String(); want != have {
+		t.Errorf("body: want %q, have %q", want, have)
+	}
+
+	m := logged.GetAllMap()
+	if want, have := "db connection reset", m["http.err.internal"]; want != have {
+		t.Errorf("http.err.internal: want %v, have %v", want, have)
+	}
+	if want, have := "please try again later", m["http.err.public"]; want != have {
+		t.Errorf("http.err.public: want %v, have %v", want, have)
+	}
+}
+
+// hijackableRecorder is an httptest.ResponseRecorder that also implements
+// http.Hijacker, so StdHandler's hijack-then-panic path can be exercised.
+type hijackableRecorder struct {
+	*httptest.ResponseRecorder
+	writeHeaderCalls int
+}
+
+func (h *hijackableRecorder) WriteHeader(code int) {
+	h.writeHeaderCalls++
+	h.ResponseRecorder.WriteHeader(code)
+}
+
+func (h *hijackableRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	server, client := net.Pipe()
+	client.Close()
+	return server, bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server)), nil
+}
+
+func TestStdHandlerHijackThenPanic(t *testing.T) {
+	t.Parallel()
+
+	var logged *ctxdata.Data
+
+	h := ctxhttp.StdHandler(ctxhttp.ReturnHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Fatalf("Hijack: %v", err)
+		}
+		conn.Close()
+		panic("after hijack")
+	}), ctxhttp.Opts{
+		Logf: func(_ context.Context, d *ctxdata.Data) {
+			logged = d
+		},
+	})
+
+	rec := &hijackableRecorder{ResponseRecorder: httptest.NewRecorder()}
+	r := httptest.NewRequest("GET", "/path", nil)
+
+	h.ServeHTTP(rec, r)
+
+	if want, have := 0, rec.writeHeaderCalls; want != have {
+		t.Errorf("WriteHeader calls after hijack: want %d, have %d", want, have)
+	}
+
+	if logged == nil {
+		t.Fatal("Logf was not called")
+	}
+	if want, have := "after hijack", logged.GetAllMap()["http.panic"]; want != have {
+		t.Errorf("http.panic: want %v, have %v", want, have)
+	}
+}
+
+func TestStdHandlerPanicWithoutLogfFallsBackToLogPrintf(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	h := ctxhttp.StdHandler(ctxhttp.ReturnHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		panic("unlogged boom")
+	}), ctxhttp.Opts{})
+
+	r := httptest.NewRequest("GET", "/path", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if want, have := http.StatusInternalServerError, w.Code; want != have {
+		t.Errorf("status: want %d, have %d", want, have)
+	}
+	if !strings.Contains(buf.String(), "unlogged boom") {
+		t.Errorf("expected fallback log to contain panic value, got %q", buf.String())
+	}
+}
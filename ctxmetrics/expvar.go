@@ -0,0 +1,62 @@
+package ctxmetrics
+
+import (
+	"expvar"
+	"strings"
+)
+
+// ExpvarCounterVec adapts an *expvar.Map into a CounterVec, joining label
+// values with a comma into a single expvar key, the same label-expansion
+// scheme used by tsweb-style expvar metrics.
+type ExpvarCounterVec struct {
+	m *expvar.Map
+}
+
+// NewExpvarCounterVec returns a CounterVec backed by m.
+func NewExpvarCounterVec(m *expvar.Map) *ExpvarCounterVec {
+	return &ExpvarCounterVec{m: m}
+}
+
+// WithLabelValues implements CounterVec.
+func (v *ExpvarCounterVec) WithLabelValues(lvs ...string) Counter {
+	return expvarCounter{m: v.m, key: strings.Join(lvs, ",")}
+}
+
+type expvarCounter struct {
+	m   *expvar.Map
+	key string
+}
+
+// Inc implements Counter.
+func (c expvarCounter) Inc() {
+	c.m.Add(c.key, 1)
+}
+
+// ExpvarHistogramVec adapts an *expvar.Map into a HistogramVec. Each labeled
+// Observer tracks a running sum and count, under "<key>.sum" and
+// "<key>.count", which is a coarser approximation of a true histogram but
+// requires no additional dependency.
+type ExpvarHistogramVec struct {
+	m *expvar.Map
+}
+
+// NewExpvarHistogramVec returns a HistogramVec backed by m.
+func NewExpvarHistogramVec(m *expvar.Map) *ExpvarHistogramVec {
+	return &ExpvarHistogramVec{m: m}
+}
+
+// WithLabelValues implements HistogramVec.
+func (v *ExpvarHistogramVec) WithLabelValues(lvs ...string) Observer {
+	return expvarObserver{m: v.m, key: strings.Join(lvs, ",")}
+}
+
+type expvarObserver struct {
+	m   *expvar.Map
+	key string
+}
+
+// Observe implements Observer.
+func (o expvarObserver) Observe(val float64) {
+	o.m.AddFloat(o.key+".sum", val)
+	o.m.Add(o.key+".count", 1)
+}
@@ -0,0 +1,38 @@
+package ctxmetrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// PromCounterVec adapts a *prometheus.CounterVec into a CounterVec. It's
+// needed because Go requires exact return-type identity for interface
+// satisfaction: (*prometheus.CounterVec).WithLabelValues returns
+// prometheus.Counter, not ctxmetrics.Counter, so the two types aren't
+// directly interchangeable without this thin wrapper.
+type PromCounterVec struct {
+	Vec *prometheus.CounterVec
+}
+
+// NewPromCounterVec returns a CounterVec backed by vec.
+func NewPromCounterVec(vec *prometheus.CounterVec) PromCounterVec {
+	return PromCounterVec{Vec: vec}
+}
+
+// WithLabelValues implements CounterVec.
+func (v PromCounterVec) WithLabelValues(lvs ...string) Counter {
+	return v.Vec.WithLabelValues(lvs...)
+}
+
+// PromHistogramVec adapts a *prometheus.HistogramVec into a HistogramVec,
+// for the same reason PromCounterVec adapts *prometheus.CounterVec.
+type PromHistogramVec struct {
+	Vec *prometheus.HistogramVec
+}
+
+// NewPromHistogramVec returns a HistogramVec backed by vec.
+func NewPromHistogramVec(vec *prometheus.HistogramVec) PromHistogramVec {
+	return PromHistogramVec{Vec: vec}
+}
+
+// WithLabelValues implements HistogramVec.
+func (v PromHistogramVec) WithLabelValues(lvs ...string) Observer {
+	return v.Vec.WithLabelValues(lvs...)
+}
@@ -0,0 +1,38 @@
+package ctxmetrics_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/peterbourgon/ctxdata"
+	"github.com/peterbourgon/ctxdata/ctxmetrics"
+)
+
+func TestRecorderPrometheus(t *testing.T) {
+	t.Parallel()
+
+	counterVec := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+	}, []string{"method", "status"})
+
+	var r ctxmetrics.Recorder
+	r.Counter(ctxmetrics.NewPromCounterVec(counterVec), "http.method", "http.status")
+
+	ctx, d := ctxdata.New(context.Background())
+	d.Set("http.method", "GET")
+	d.Set("http.status", 200)
+
+	r.Observe(ctx)
+	r.Observe(ctx)
+
+	var m dto.Metric
+	if err := counterVec.WithLabelValues("GET", "200").Write(&m); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if want, have := float64(2), m.GetCounter().GetValue(); want != have {
+		t.Errorf("counter value: want %v, have %v", want, have)
+	}
+}
@@ -0,0 +1,125 @@
+// Package ctxmetrics derives counter and histogram observations from the
+// key/value pairs collected in a ctxdata.Data, so per-request metadata can
+// double as a source for structured metrics.
+package ctxmetrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/peterbourgon/ctxdata"
+)
+
+// Counter is incremented by one per observation.
+type Counter interface {
+	Inc()
+}
+
+// CounterVec produces a labeled Counter. See PromCounterVec for an adapter
+// over *prometheus.CounterVec, and ExpvarCounterVec for one over expvar.Map.
+type CounterVec interface {
+	WithLabelValues(lvs ...string) Counter
+}
+
+// Observer records individual observations, such as request durations.
+type Observer interface {
+	Observe(v float64)
+}
+
+// HistogramVec produces a labeled Observer. See PromHistogramVec for an
+// adapter over *prometheus.HistogramVec, and ExpvarHistogramVec for one over
+// expvar.Map.
+type HistogramVec interface {
+	WithLabelValues(lvs ...string) Observer
+}
+
+// Recorder holds a set of metric bindings, each keyed on the ctxdata fields
+// that supply its labels and, for histograms, its observed value. The zero
+// value is a Recorder with no bindings.
+type Recorder struct {
+	counters   []counterBinding
+	histograms []histogramBinding
+}
+
+type counterBinding struct {
+	vec    CounterVec
+	labels []string
+}
+
+type histogramBinding struct {
+	vec    HistogramVec
+	value  string
+	labels []string
+}
+
+// Counter registers vec to be incremented on every Observe call, labeled with
+// the values of the given ctxdata keys, in order.
+func (r *Recorder) Counter(vec CounterVec, labels ...string) {
+	r.counters = append(r.counters, counterBinding{vec: vec, labels: labels})
+}
+
+// Histogram registers vec to observe the numeric value stored under the
+// value key on every Observe call, labeled with the values of the given
+// ctxdata keys, in order. Values of type float64, float32, int, int64 and
+// time.Duration (observed in seconds) are accepted; if the value key isn't
+// present, or its value is of any other type, the binding is skipped.
+func (r *Recorder) Histogram(vec HistogramVec, value string, labels ...string) {
+	r.histograms = append(r.histograms, histogramBinding{vec: vec, value: value, labels: labels})
+}
+
+// Observe reads the Data found in ctx and updates every registered binding.
+// It's a no-op if ctx has no Data.
+func (r *Recorder) Observe(ctx context.Context) {
+	d := ctxdata.From(ctx)
+	if d == nil {
+		return
+	}
+	m := d.GetAllMap()
+
+	for _, b := range r.counters {
+		b.vec.WithLabelValues(labelValues(m, b.labels)...).Inc()
+	}
+
+	for _, b := range r.histograms {
+		v, ok := toFloat64(m[b.value])
+		if !ok {
+			continue
+		}
+		b.vec.WithLabelValues(labelValues(m, b.labels)...).Observe(v)
+	}
+}
+
+// ObserveOnFinish returns a func(context.Context, *ctxdata.Data) suitable for
+// assignment to ctxhttp.Opts.Logf, so Observe is called once per request in
+// the middleware's deferred block.
+func (r *Recorder) ObserveOnFinish() func(context.Context, *ctxdata.Data) {
+	return func(ctx context.Context, _ *ctxdata.Data) {
+		r.Observe(ctx)
+	}
+}
+
+func labelValues(m map[string]interface{}, keys []string) []string {
+	lvs := make([]string, len(keys))
+	for i, k := range keys {
+		lvs[i] = fmt.Sprintf("%v", m[k])
+	}
+	return lvs
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case time.Duration:
+		return n.Seconds(), true
+	default:
+		return 0, false
+	}
+}
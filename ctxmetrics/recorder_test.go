@@ -0,0 +1,60 @@
+package ctxmetrics_test
+
+import (
+	"context"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/peterbourgon/ctxdata"
+	"github.com/peterbourgon/ctxdata/ctxmetrics"
+)
+
+func TestRecorderExpvar(t *testing.T) {
+	t.Parallel()
+
+	requestsMap, durationsMap := new(expvar.Map), new(expvar.Map)
+	requests := ctxmetrics.NewExpvarCounterVec(requestsMap)
+	durations := ctxmetrics.NewExpvarHistogramVec(durationsMap)
+
+	var r ctxmetrics.Recorder
+	r.Counter(requests, "http.method", "http.status")
+	r.Histogram(durations, "http.duration", "http.method")
+
+	ctx, d := ctxdata.New(context.Background())
+	d.Set("http.method", "GET")
+	d.Set("http.status", 200)
+	d.Set("http.duration", 250*time.Millisecond)
+
+	r.Observe(ctx)
+	r.Observe(ctx)
+
+	if want, have := `{"GET,200": 2}`, requestsMap.String(); want != have {
+		t.Errorf("requests: want %s, have %s", want, have)
+	}
+
+	if want, have := `{"GET.count": 2, "GET.sum": 0.5}`, durationsMap.String(); want != have {
+		t.Errorf("durations: want %s, have %s", want, have)
+	}
+}
+
+func TestObserveOnFinish(t *testing.T) {
+	t.Parallel()
+
+	requestsMap := new(expvar.Map)
+	requests := ctxmetrics.NewExpvarCounterVec(requestsMap)
+
+	var r ctxmetrics.Recorder
+	r.Counter(requests, "http.method")
+
+	logf := r.ObserveOnFinish()
+
+	ctx, d := ctxdata.New(context.Background())
+	d.Set("http.method", "POST")
+
+	logf(ctx, d)
+
+	if want, have := `{"POST": 1}`, requestsMap.String(); want != have {
+		t.Errorf("requests: want %s, have %s", want, have)
+	}
+}
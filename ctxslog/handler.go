@@ -0,0 +1,69 @@
+// Package ctxslog provides a log/slog Handler that enriches every log record
+// with the key/value pairs collected in a ctxdata.Data.
+package ctxslog
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/peterbourgon/ctxdata"
+)
+
+// Opts control the behavior of a Handler.
+type Opts struct {
+	// Prefix, if set, is prepended to every ctxdata key before it's added
+	// to a record as a slog.Attr.
+	Prefix string
+
+	// Include, if set, is called for every ctxdata key, and the key is
+	// added to the record only if it returns true. If nil, all keys are
+	// included.
+	Include func(key string) bool
+}
+
+// NewHandler wraps inner so that every call to Handle first appends the
+// key/value pairs from the ctxdata.Data found in ctx, in the order they were
+// Set, as slog.Attrs, before delegating to inner. If ctx has no Data, or the
+// Data is empty, inner is called unmodified.
+func NewHandler(inner slog.Handler, opts Opts) slog.Handler {
+	return &handler{inner: inner, opts: opts}
+}
+
+type handler struct {
+	inner slog.Handler
+	opts  Opts
+}
+
+// Enabled implements slog.Handler.
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (h *handler) Handle(ctx context.Context, record slog.Record) error {
+	for _, kv := range ctxdata.From(ctx).GetAllSlice() {
+		if h.opts.Include != nil && !h.opts.Include(kv.Key) {
+			continue
+		}
+		key := kv.Key
+		if h.opts.Prefix != "" {
+			key = h.opts.Prefix + key
+		}
+		record.AddAttrs(slog.Any(key, kv.Val))
+	}
+	return h.inner.Handle(ctx, record)
+}
+
+// WithAttrs implements slog.Handler. The returned Handler delegates to the
+// inner handler's WithAttrs, so the added attrs are subject to the inner
+// handler's own grouping.
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &handler{inner: h.inner.WithAttrs(attrs), opts: h.opts}
+}
+
+// WithGroup implements slog.Handler. ctxdata attrs added in Handle are still
+// nested under any open groups, because they're added to the record before
+// it's passed to the (already grouped) inner handler.
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{inner: h.inner.WithGroup(name), opts: h.opts}
+}
@@ -0,0 +1,90 @@
+package ctxslog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/peterbourgon/ctxdata"
+	"github.com/peterbourgon/ctxdata/ctxslog"
+)
+
+func TestHandlerInjectsCtxdata(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	inner := slog.NewJSONHandler(&buf, nil)
+	logger := slog.New(ctxslog.NewHandler(inner, ctxslog.Opts{}))
+
+	ctx, d := ctxdata.New(context.Background())
+	d.Set("http.method", "GET")
+	d.Set("http.status", 200)
+
+	logger.InfoContext(ctx, "request served")
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if want, have := "GET", got["http.method"]; want != have {
+		t.Errorf("http.method: want %v, have %v", want, have)
+	}
+	if want, have := float64(200), got["http.status"]; want != have {
+		t.Errorf("http.status: want %v, have %v", want, have)
+	}
+}
+
+func TestHandlerPrefixAndInclude(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	inner := slog.NewJSONHandler(&buf, nil)
+	logger := slog.New(ctxslog.NewHandler(inner, ctxslog.Opts{
+		Prefix:  "ctx.",
+		Include: func(key string) bool { return key != "secret" },
+	}))
+
+	ctx, d := ctxdata.New(context.Background())
+	d.Set("user.id", "u1")
+	d.Set("secret", "do-not-log")
+
+	logger.InfoContext(ctx, "hello")
+
+	out := buf.String()
+	if !strings.Contains(out, `"ctx.user.id":"u1"`) {
+		t.Errorf("expected prefixed key in output, got %s", out)
+	}
+	if strings.Contains(out, "do-not-log") {
+		t.Errorf("expected excluded key to be omitted, got %s", out)
+	}
+}
+
+func TestHandlerWithGroup(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	inner := slog.NewJSONHandler(&buf, nil)
+	logger := slog.New(ctxslog.NewHandler(inner, ctxslog.Opts{})).WithGroup("req")
+
+	ctx, d := ctxdata.New(context.Background())
+	d.Set("path", "/foo")
+
+	logger.InfoContext(ctx, "hello")
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	group, ok := got["req"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected nested req group, got %v", got)
+	}
+	if want, have := "/foo", group["path"]; want != have {
+		t.Errorf("path: want %v, have %v", want, have)
+	}
+}
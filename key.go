@@ -0,0 +1,66 @@
+package ctxdata
+
+import "errors"
+
+// ErrIncompatibleType is returned by Key.Get when the value stored under the
+// key isn't a T, as decided by a type assertion.
+var ErrIncompatibleType = errors.New("incompatible type")
+
+// Key is a typed handle for a single piece of request metadata. It wraps the
+// same untyped Set/Get machinery as Data, but gives callers compile-time
+// type safety, so user-defined metadata no longer needs to round-trip
+// through interface{}.
+type Key[T any] struct {
+	name string
+}
+
+// NewKey constructs a Key with the given name. The name is used as the
+// underlying key in Data's storage, so it's subject to the same
+// set-overwrites-and-moves-to-end semantics as Data.Set.
+func NewKey[T any](name string) Key[T] {
+	return Key[T]{name: name}
+}
+
+// Set val for the key in d. If this method is called on a nil Data pointer,
+// it returns ErrNoData.
+func (k Key[T]) Set(d *Data, val T) error {
+	return d.Set(k.name, val)
+}
+
+// Get the value previously Set for the key in d. It returns ErrNotFound if
+// the key hasn't been Set, or ErrIncompatibleType if the stored value isn't a
+// T. If this method is called on a nil Data pointer, it returns ErrNoData.
+func (k Key[T]) Get(d *Data) (T, error) {
+	var zero T
+
+	val, err := d.Get(k.name)
+	if err != nil {
+		return zero, err
+	}
+
+	v, ok := val.(T)
+	if !ok {
+		return zero, ErrIncompatibleType
+	}
+
+	return v, nil
+}
+
+// MustGet is like Get, but panics if the value isn't present, or isn't a T.
+func (k Key[T]) MustGet(d *Data) T {
+	v, err := k.Get(d)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Default returns the value Set for the key in d, or def if the key hasn't
+// been Set, or its stored value isn't a T.
+func (k Key[T]) Default(d *Data, def T) T {
+	v, err := k.Get(d)
+	if err != nil {
+		return def
+	}
+	return v
+}
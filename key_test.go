@@ -0,0 +1,67 @@
+package ctxdata_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/peterbourgon/ctxdata"
+)
+
+func TestKey(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		ID string
+	}
+
+	userKey := ctxdata.NewKey[user]("user")
+	countKey := ctxdata.NewKey[int]("count")
+
+	_, d := ctxdata.New(context.Background())
+
+	if _, err := userKey.Get(d); err != ctxdata.ErrNotFound {
+		t.Fatalf("Get before Set: want %v, have %v", ctxdata.ErrNotFound, err)
+	}
+
+	if want, have := (user{ID: "anon"}), userKey.Default(d, user{ID: "anon"}); want != have {
+		t.Errorf("Default before Set: want %v, have %v", want, have)
+	}
+
+	if err := userKey.Set(d, user{ID: "u1"}); err != nil {
+		t.Fatalf("Set: unexpected error %v", err)
+	}
+
+	got, err := userKey.Get(d)
+	if err != nil {
+		t.Fatalf("Get: unexpected error %v", err)
+	}
+	if want, have := "u1", got.ID; want != have {
+		t.Errorf("Get: ID: want %q, have %q", want, have)
+	}
+
+	if want, have := "u1", userKey.MustGet(d).ID; want != have {
+		t.Errorf("MustGet: ID: want %q, have %q", want, have)
+	}
+
+	d.Set("count", "not an int")
+	if _, err := countKey.Get(d); err != ctxdata.ErrIncompatibleType {
+		t.Fatalf("Get with wrong type: want %v, have %v", ctxdata.ErrIncompatibleType, err)
+	}
+	if want, have := 42, countKey.Default(d, 42); want != have {
+		t.Errorf("Default with wrong type: want %d, have %d", want, have)
+	}
+}
+
+func TestKeyNilData(t *testing.T) {
+	t.Parallel()
+
+	k := ctxdata.NewKey[string]("k")
+	d := ctxdata.From(context.Background())
+
+	if err := k.Set(d, "v"); err != ctxdata.ErrNoData {
+		t.Errorf("Set: want %v, have %v", ctxdata.ErrNoData, err)
+	}
+	if _, err := k.Get(d); err != ctxdata.ErrNoData {
+		t.Errorf("Get: want %v, have %v", ctxdata.ErrNoData, err)
+	}
+}
@@ -0,0 +1,54 @@
+package ctxdata
+
+import "errors"
+
+// VisibleError wraps an internal error with an HTTP status code and a
+// message that is safe to show to the end user. Construct one with Wrap, and
+// recover one from an arbitrary error chain with As, typically in HTTP
+// middleware that needs to render a user-safe response while keeping the
+// original error available for observability.
+type VisibleError struct {
+	err    error
+	status int
+	public string
+}
+
+// Wrap err in a VisibleError with the given HTTP status code and public,
+// user-safe message. The internal error is preserved and remains available
+// via Unwrap or As.
+func Wrap(err error, status int, public string) *VisibleError {
+	return &VisibleError{err: err, status: status, public: public}
+}
+
+// Error implements the error interface, returning the internal error's
+// message. Use Public for the message that's safe to show to users.
+func (e *VisibleError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped internal error, for use with errors.Is and
+// errors.As.
+func (e *VisibleError) Unwrap() error {
+	return e.err
+}
+
+// Status returns the HTTP status code associated with the error.
+func (e *VisibleError) Status() int {
+	return e.status
+}
+
+// Public returns the user-safe message associated with the error.
+func (e *VisibleError) Public() string {
+	return e.public
+}
+
+// As extracts a *VisibleError from err's chain, following the same
+// unwrapping rules as errors.As. It returns false if err doesn't contain a
+// VisibleError.
+func As(err error) (*VisibleError, bool) {
+	var ve *VisibleError
+	if errors.As(err, &ve) {
+		return ve, true
+	}
+	return nil, false
+}
@@ -0,0 +1,44 @@
+package ctxdata_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/peterbourgon/ctxdata"
+)
+
+func TestVisibleError(t *testing.T) {
+	t.Parallel()
+
+	internal := errors.New("connection refused")
+	err := ctxdata.Wrap(internal, http.StatusServiceUnavailable, "please try again later")
+
+	if want, have := internal.Error(), err.Error(); want != have {
+		t.Errorf("Error: want %q, have %q", want, have)
+	}
+	if want, have := http.StatusServiceUnavailable, err.Status(); want != have {
+		t.Errorf("Status: want %d, have %d", want, have)
+	}
+	if want, have := "please try again later", err.Public(); want != have {
+		t.Errorf("Public: want %q, have %q", want, have)
+	}
+	if want, have := internal, errors.Unwrap(err); want != have {
+		t.Errorf("Unwrap: want %v, have %v", want, have)
+	}
+
+	wrapped := fmt.Errorf("handler failed: %w", err)
+
+	ve, ok := ctxdata.As(wrapped)
+	if !ok {
+		t.Fatal("As: expected to find a VisibleError")
+	}
+	if want, have := "please try again later", ve.Public(); want != have {
+		t.Errorf("As: Public: want %q, have %q", want, have)
+	}
+
+	if _, ok := ctxdata.As(internal); ok {
+		t.Error("As: expected not to find a VisibleError")
+	}
+}